@@ -0,0 +1,58 @@
+package dnsimple
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// zoneCacheEntry holds a cached zone listing and when it expires.
+type zoneCacheEntry struct {
+	records   []libdns.Record
+	expiresAt time.Time
+}
+
+// zoneCache caches the result of listing a zone's records, keyed by zone name,
+// so lookups that follow a listing (e.g. the DeleteRecords fallback path
+// resolving record IDs by name) don't re-fetch and re-paginate the whole zone.
+// An entry lasts until it expires or the zone is modified through the Provider.
+type zoneCache struct {
+	mu      sync.RWMutex
+	entries map[string]zoneCacheEntry
+}
+
+// get returns a copy of the cached records for zone, if present and not expired.
+func (c *zoneCache) get(zone string) ([]libdns.Record, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[zone]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+
+	records := make([]libdns.Record, len(entry.records))
+	copy(records, entry.records)
+	return records, true
+}
+
+// set stores a copy of records for zone, expiring after ttl.
+func (c *zoneCache) set(zone string, records []libdns.Record, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]zoneCacheEntry)
+	}
+	cp := make([]libdns.Record, len(records))
+	copy(cp, records)
+	c.entries[zone] = zoneCacheEntry{records: cp, expiresAt: time.Now().Add(ttl)}
+}
+
+// invalidate removes any cached entry for zone.
+func (c *zoneCache) invalidate(zone string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, zone)
+}
@@ -0,0 +1,72 @@
+package dnsimple
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Environment variables honored when the corresponding Provider field is left
+// unset, mirroring the conventions used by lego's DNSimple provider.
+const (
+	envOAuthToken = "DNSIMPLE_OAUTH_TOKEN"
+	envBaseURL    = "DNSIMPLE_BASE_URL"
+	envSandbox    = "DNSIMPLE_SANDBOX"
+)
+
+// sandboxBaseURL is the API host used when sandbox mode is requested and no
+// explicit APIURL/DNSIMPLE_BASE_URL is set.
+const sandboxBaseURL = "https://api.sandbox.dnsimple.com"
+
+// Config holds the settings needed to construct a Provider via NewProvider.
+// Unlike Provider, which is typically populated from a caddy/libdns config
+// file, Config is meant for callers configuring the module programmatically
+// or from the environment.
+type Config struct {
+	APIAccessToken string
+	AccountID      string
+	APIURL         string
+	Sandbox        bool
+
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+// NewProvider builds a Provider from config, falling back to the
+// DNSIMPLE_OAUTH_TOKEN, DNSIMPLE_BASE_URL and DNSIMPLE_SANDBOX environment
+// variables when the access token, API URL or sandbox mode is left unset (the
+// other fields have no environment fallback), and eagerly validates the resulting
+// access token against the DNSimple API before returning.
+func NewProvider(ctx context.Context, config Config) (*Provider, error) {
+	p := &Provider{
+		APIAccessToken: config.APIAccessToken,
+		AccountID:      config.AccountID,
+		APIURL:         config.APIURL,
+		Sandbox:        config.Sandbox,
+		MaxRetries:     config.MaxRetries,
+		RetryBaseDelay: config.RetryBaseDelay,
+		RetryMaxDelay:  config.RetryMaxDelay,
+	}
+	if err := p.initClient(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// applyEnvDefaults fills in APIAccessToken and APIURL from the environment
+// when they haven't been set explicitly, and resolves sandbox mode to the
+// DNSimple sandbox API host.
+func (p *Provider) applyEnvDefaults() {
+	if p.APIAccessToken == "" {
+		p.APIAccessToken = os.Getenv(envOAuthToken)
+	}
+	if p.APIURL == "" {
+		p.APIURL = os.Getenv(envBaseURL)
+	}
+
+	sandbox := p.Sandbox || os.Getenv(envSandbox) == "true"
+	if p.APIURL == "" && sandbox {
+		p.APIURL = sandboxBaseURL
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,55 +19,148 @@ type Provider struct {
 	AccountID      string `json:"account_id,omitempty"`
 	APIURL         string `json:"api_url,omitempty"`
 
-	client dnsimple.Client
-	once   sync.Once
+	// Sandbox selects DNSimple's sandbox API (https://api.sandbox.dnsimple.com)
+	// when neither APIURL nor DNSIMPLE_BASE_URL is set. DNSIMPLE_SANDBOX=true has
+	// the same effect.
+	Sandbox bool `json:"sandbox,omitempty"`
+
+	// MaxRetries, RetryBaseDelay and RetryMaxDelay configure the retry/backoff
+	// behavior applied to requests that hit DNSimple's rate limiting (HTTP 429)
+	// or a transient upstream error. Zero values fall back to sane defaults.
+	MaxRetries     int           `json:"max_retries,omitempty"`
+	RetryBaseDelay time.Duration `json:"retry_base_delay,omitempty"`
+	RetryMaxDelay  time.Duration `json:"retry_max_delay,omitempty"`
+
+	// CacheTTL, when non-zero, enables caching of a zone's record listing for
+	// that long, so a listing fetched by GetRecords is reused by later
+	// GetRecords calls and by the DeleteRecords fallback path that resolves IDs
+	// by name. Every AppendRecords, SetRecords and DeleteRecords call drops the
+	// zone's cached listing, so consecutive DeleteRecords calls each fetch it
+	// again. Zero disables caching. 30s is a reasonable value to opt in with.
+	CacheTTL time.Duration `json:"cache_ttl,omitempty"`
+
+	client *dnsimple.Client
 	mutex  sync.Mutex
+	cache  zoneCache
 }
 
-// initClient will initialize the DNSimple API client with the provided access token and
-// store the client in the Provider struct, along with setting the API URL and Account ID.
-func (p *Provider) initClient(ctx context.Context) {
-	p.once.Do(func() {
-		// Create new DNSimple client using the provided access token.
-		tc := dnsimple.StaticTokenHTTPClient(ctx, p.APIAccessToken)
-		c := *dnsimple.NewClient(tc)
-		// Set the API URL if using a non-default API hostname (e.g. sandbox).
-		if p.APIURL != "" {
-			c.BaseURL = p.APIURL
-		}
-		// If no Account ID is provided, we can call the API to get the corresponding
-		// account id for the provided access token.
-		if p.AccountID == "" {
-			resp, _ := c.Identity.Whoami(context.Background())
-			accountID := strconv.FormatInt(resp.Data.Account.ID, 10)
-			p.AccountID = accountID
-		}
+// initClient initializes the DNSimple API client with the provided access token and
+// stores it in the Provider struct, along with setting the API URL and Account ID. It is
+// called by every exported method (which hold p.mutex for their duration, so initClient
+// doesn't need its own locking), but is also safe to call directly (e.g. via NewProvider)
+// to surface initialization errors eagerly instead of on first use.
+//
+// It is idempotent on success (p.client is only set once that happens) but intentionally
+// does not memoize failure: a transient error (network blip, 500, auth hiccup) on one call
+// must not leave p.client nil while reporting success on the next.
+func (p *Provider) initClient(ctx context.Context) error {
+	if p.client != nil {
+		return nil
+	}
 
-		p.client = c
-	})
+	p.applyEnvDefaults()
+
+	// Create new DNSimple client using the provided access token, wrapping its
+	// transport with retry/backoff handling so rate-limit and transient upstream
+	// errors are retried instead of surfacing to the caller.
+	tc := dnsimple.StaticTokenHTTPClient(ctx, p.APIAccessToken)
+	tc.Transport = p.retryTransport(tc.Transport)
+	c := dnsimple.NewClient(tc)
+	// Set the API URL if using a non-default API hostname (e.g. sandbox).
+	if p.APIURL != "" {
+		c.BaseURL = p.APIURL
+	}
+	// Validate the token and, if no Account ID is provided, look up the account id
+	// corresponding to it.
+	resp, err := c.Identity.Whoami(ctx)
+	if err != nil {
+		return fmt.Errorf("authenticating with DNSimple: %w", err)
+	}
+	if p.AccountID == "" {
+		p.AccountID = strconv.FormatInt(resp.Data.Account.ID, 10)
+	}
+
+	p.client = c
+	return nil
+}
+
+// relativeName strips the zone suffix from a libdns record name, returning the
+// bare name DNSimple expects. An empty name or a name equal to the zone itself
+// is treated as the zone apex and represented as "".
+func relativeName(name, zone string) string {
+	name = strings.TrimSuffix(name, ".")
+	zone = strings.TrimSuffix(zone, ".")
+	if name == "" || name == "@" || name == zone {
+		return ""
+	}
+	return strings.TrimSuffix(name, "."+zone)
+}
+
+// toZoneRecordAttributes converts a libdns.Record into the attributes DNSimple's
+// API expects when creating or updating a zone record.
+func toZoneRecordAttributes(zone string, r libdns.Record) dnsimple.ZoneRecordAttributes {
+	return dnsimple.ZoneRecordAttributes{
+		Name:     dnsimple.String(relativeName(r.Name, zone)),
+		Type:     r.Type,
+		Content:  r.Value,
+		TTL:      int(r.TTL.Seconds()),
+		Priority: int(r.Priority),
+	}
+}
+
+// toLibdnsRecord converts a DNSimple zone record into a libdns.Record.
+func toLibdnsRecord(r dnsimple.ZoneRecord) libdns.Record {
+	return libdns.Record{
+		ID:       strconv.FormatInt(r.ID, 10),
+		Type:     r.Type,
+		Name:     r.Name,
+		Value:    r.Content,
+		TTL:      time.Duration(r.TTL) * time.Second,
+		Priority: uint(r.Priority),
+	}
 }
 
 // GetRecords lists all the records in the zone.
 func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	p.initClient(ctx)
+	if err := p.initClient(ctx); err != nil {
+		return nil, err
+	}
 
-	var records []libdns.Record
+	return p.getRecords(ctx, zone)
+}
 
-	resp, err := p.client.Zones.ListRecords(ctx, p.AccountID, zone, &dnsimple.ZoneRecordListOptions{})
-	if err != nil {
-		return nil, err
+// getRecords is the implementation behind GetRecords, factored out so callers
+// that already hold p.mutex and have called initClient (such as the
+// DeleteRecords ID-resolution fallback) can reuse it without deadlocking on
+// a second mutex acquisition.
+func (p *Provider) getRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	if cached, ok := p.cache.get(zone); ok {
+		return cached, nil
 	}
-	for _, r := range resp.Data {
-		records = append(records, libdns.Record{
-			ID:       strconv.FormatInt(r.ID, 10),
-			Type:     r.Type,
-			Name:     r.Name,
-			Value:    r.Content,
-			TTL:      time.Duration(r.TTL),
-			Priority: uint(r.Priority),
+
+	var records []libdns.Record
+
+	page := 1
+	for {
+		resp, err := p.client.Zones.ListRecords(ctx, p.AccountID, zone, &dnsimple.ZoneRecordListOptions{
+			ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(page)},
 		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Data {
+			records = append(records, toLibdnsRecord(r))
+		}
+		if resp.Pagination.CurrentPage >= resp.Pagination.TotalPages {
+			break
+		}
+		page++
+	}
+
+	if p.CacheTTL > 0 {
+		p.cache.set(zone, records, p.CacheTTL)
 	}
 
 	return records, nil
@@ -76,26 +170,111 @@ func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record
 func (p *Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	p.initClient(ctx)
+	if err := p.initClient(ctx); err != nil {
+		return nil, err
+	}
+	defer p.cache.invalidate(zone)
+
+	var appended []libdns.Record
+	for _, r := range records {
+		resp, err := p.client.Zones.CreateRecord(ctx, p.AccountID, zone, toZoneRecordAttributes(zone, r))
+		if err != nil {
+			return appended, fmt.Errorf("creating record %q: %w", r.Name, err)
+		}
+		appended = append(appended, toLibdnsRecord(*resp.Data))
+	}
 
-	return nil, fmt.Errorf("TODO: not implemented")
+	return appended, nil
 }
 
 // SetRecords sets the records in the zone, either by updating existing records or creating new ones.
 // It returns the updated records.
+//
+// For a record with no ID, the existing record to update is looked up by (Name, Type). If more
+// than one existing record shares that (Name, Type) — e.g. multiple TXT records at the same name
+// during multi-SAN ACME issuance — which one to update is ambiguous, so SetRecords returns an error
+// rather than guessing; callers in that situation must set Record.ID explicitly.
 func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	p.initClient(ctx)
+	if err := p.initClient(ctx); err != nil {
+		return nil, err
+	}
+	defer p.cache.invalidate(zone)
 
-	return nil, fmt.Errorf("TODO: not implemented")
+	var set []libdns.Record
+	for _, r := range records {
+		id := r.ID
+		if id == "" {
+			existingID, err := p.findRecordID(ctx, zone, r)
+			if err != nil {
+				return set, fmt.Errorf("looking up record %q: %w", r.Name, err)
+			}
+			id = existingID
+		}
+
+		attrs := toZoneRecordAttributes(zone, r)
+		if id == "" {
+			resp, err := p.client.Zones.CreateRecord(ctx, p.AccountID, zone, attrs)
+			if err != nil {
+				return set, fmt.Errorf("creating record %q: %w", r.Name, err)
+			}
+			set = append(set, toLibdnsRecord(*resp.Data))
+			continue
+		}
+
+		recordID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return set, fmt.Errorf("parsing record id %q: %w", id, err)
+		}
+		resp, err := p.client.Zones.UpdateRecord(ctx, p.AccountID, zone, recordID, attrs)
+		if err != nil {
+			return set, fmt.Errorf("updating record %q: %w", r.Name, err)
+		}
+		set = append(set, toLibdnsRecord(*resp.Data))
+	}
+
+	return set, nil
+}
+
+// findRecordID looks up the existing record matching r's name and type, returning its
+// DNSimple record ID, or "" if no match exists. If more than one existing record matches,
+// the lookup is ambiguous and findRecordID returns an error rather than picking one arbitrarily.
+func (p *Provider) findRecordID(ctx context.Context, zone string, r libdns.Record) (string, error) {
+	resp, err := p.client.Zones.ListRecords(ctx, p.AccountID, zone, &dnsimple.ZoneRecordListOptions{
+		Name: dnsimple.String(relativeName(r.Name, zone)),
+		Type: dnsimple.String(r.Type),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	name := relativeName(r.Name, zone)
+	var matches []string
+	for _, fr := range resp.Data {
+		if fr.Name == name && fr.Type == r.Type {
+			matches = append(matches, strconv.FormatInt(fr.ID, 10))
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%d existing records match name %q type %q in zone %q; set Record.ID to disambiguate which one to update", len(matches), name, r.Type, zone)
+	}
 }
 
 // DeleteRecords deletes the records from the zone. It returns the records that were deleted.
 func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	p.initClient(ctx)
+	if err := p.initClient(ctx); err != nil {
+		return nil, err
+	}
+	defer p.cache.invalidate(zone)
 
 	var deleted []libdns.Record
 	var failed []libdns.Record
@@ -118,6 +297,7 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 		resp, err := p.client.Zones.DeleteRecord(ctx, p.AccountID, zone, id)
 		if err != nil {
 			failed = append(failed, r)
+			continue
 		}
 		// See https://developer.dnsimple.com/v2/zones/records/#deleteZoneRecord for API response codes
 		switch resp.HTTPResponse.StatusCode {
@@ -135,13 +315,15 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 	// GetRecords and comparing the record name. If we're able to find it, we'll delete it, otherwise
 	// we'll append it to our list of failed to delete records.
 	if len(noID) > 0 {
-		fetchedRecords, err := p.GetRecords(ctx, zone)
+		fetchedRecords, err := p.getRecords(ctx, zone)
 		if err != nil {
 			fmt.Printf("Failed to populate IDs for records where one wasn't provided, err: %s", err.Error())
 		} else {
 			for _, r := range noID {
+				found := false
 				for _, fr := range fetchedRecords {
-					if fr.Name == r.Name {
+					if fr.Name == relativeName(r.Name, zone) && fr.Type == r.Type {
+						found = true
 						id, err := strconv.ParseInt(fr.ID, 10, 64)
 						if err != nil {
 							failed = append(failed, r)
@@ -150,6 +332,7 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 						resp, err := p.client.Zones.DeleteRecord(ctx, p.AccountID, zone, id)
 						if err != nil {
 							failed = append(failed, r)
+							break
 						}
 						// See https://developer.dnsimple.com/v2/zones/records/#deleteZoneRecord for API response codes
 						switch resp.HTTPResponse.StatusCode {
@@ -165,15 +348,12 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 						break
 					}
 				}
-				fmt.Printf("Could not figure out ID for record: %s", r)
-				failed = append(failed, r)
+				if !found {
+					failed = append(failed, r)
+				}
 			}
 		}
 	}
-	// Print out all the records we failed to delete.
-	for _, r := range failed {
-		fmt.Printf("Failed to delete record: %s", r)
-	}
 
 	return deleted, nil
 }
@@ -0,0 +1,241 @@
+package dnsimple
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// newTestServer starts an httptest server that always answers the
+// Identity.Whoami call initClient makes, plus whatever additional handlers
+// the caller registers on the returned mux.
+func newTestServer(t *testing.T, mux *http.ServeMux) *httptest.Server {
+	t.Helper()
+
+	mux.HandleFunc("/v2/whoami", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"account": map[string]any{"id": 1},
+			},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestInitClientHonorsAPIURL ensures requests are actually sent to the
+// configured APIURL (e.g. a sandbox or test server) rather than DNSimple's
+// production host.
+func TestInitClientHonorsAPIURL(t *testing.T) {
+	var gotWhoami bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/whoami", func(w http.ResponseWriter, r *http.Request) {
+		gotWhoami = true
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"account": map[string]any{"id": 42},
+			},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := &Provider{
+		APIAccessToken: "test-token",
+		APIURL:         server.URL,
+	}
+
+	if err := p.initClient(context.Background()); err != nil {
+		t.Fatalf("initClient returned error: %v", err)
+	}
+	if !gotWhoami {
+		t.Fatal("expected initClient to call Whoami against the configured APIURL, but the test server never saw a request")
+	}
+	if p.AccountID != "42" {
+		t.Fatalf("AccountID = %q, want %q", p.AccountID, "42")
+	}
+}
+
+// zoneRecordJSON mirrors the subset of DNSimple's zone record representation
+// this package reads and writes.
+type zoneRecordJSON struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	TTL      int    `json:"ttl"`
+	Priority int    `json:"priority"`
+}
+
+func TestAppendRecords(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/1/zones/example.com/records", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("unexpected method %s", r.Method)
+		}
+		var attrs zoneRecordJSON
+		if err := json.NewDecoder(r.Body).Decode(&attrs); err != nil {
+			t.Fatalf("decoding request body: %v", err)
+		}
+		if attrs.Name != "_acme-challenge" {
+			t.Fatalf("Name = %q, want the zone suffix stripped (%q)", attrs.Name, "_acme-challenge")
+		}
+		attrs.ID = 1
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"data": attrs})
+	})
+	server := newTestServer(t, mux)
+
+	p := &Provider{APIAccessToken: "test-token", APIURL: server.URL}
+
+	records, err := p.AppendRecords(context.Background(), "example.com", []libdns.Record{
+		{Type: "TXT", Name: "_acme-challenge.example.com", Value: "token", TTL: 60 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("AppendRecords returned error: %v", err)
+	}
+	if len(records) != 1 || records[0].ID != "1" {
+		t.Fatalf("AppendRecords = %+v, want a single record with ID 1", records)
+	}
+}
+
+func TestSetRecordsCreatesWhenNoMatchExists(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/1/zones/example.com/records", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"data":       []zoneRecordJSON{},
+				"pagination": map[string]any{"current_page": 1, "total_pages": 1},
+			})
+		case http.MethodPost:
+			var attrs zoneRecordJSON
+			if err := json.NewDecoder(r.Body).Decode(&attrs); err != nil {
+				t.Fatalf("decoding request body: %v", err)
+			}
+			attrs.ID = 2
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"data": attrs})
+		default:
+			t.Fatalf("unexpected method %s", r.Method)
+		}
+	})
+	server := newTestServer(t, mux)
+
+	p := &Provider{APIAccessToken: "test-token", APIURL: server.URL}
+
+	records, err := p.SetRecords(context.Background(), "example.com", []libdns.Record{
+		{Type: "TXT", Name: "_acme-challenge.example.com", Value: "token", TTL: 60 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("SetRecords returned error: %v", err)
+	}
+	if len(records) != 1 || records[0].ID != "2" {
+		t.Fatalf("SetRecords = %+v, want a single created record with ID 2", records)
+	}
+}
+
+func TestSetRecordsUpdatesWhenMatchExists(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/1/zones/example.com/records", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Fatalf("unexpected method %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []zoneRecordJSON{
+				{ID: 3, Type: "TXT", Name: "_acme-challenge", Content: "old-token"},
+			},
+			"pagination": map[string]any{"current_page": 1, "total_pages": 1},
+		})
+	})
+	mux.HandleFunc("/v2/1/zones/example.com/records/3", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Fatalf("unexpected method %s", r.Method)
+		}
+		var attrs zoneRecordJSON
+		if err := json.NewDecoder(r.Body).Decode(&attrs); err != nil {
+			t.Fatalf("decoding request body: %v", err)
+		}
+		attrs.ID = 3
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"data": attrs})
+	})
+	server := newTestServer(t, mux)
+
+	p := &Provider{APIAccessToken: "test-token", APIURL: server.URL}
+
+	records, err := p.SetRecords(context.Background(), "example.com", []libdns.Record{
+		{Type: "TXT", Name: "_acme-challenge.example.com", Value: "new-token", TTL: 60 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("SetRecords returned error: %v", err)
+	}
+	if len(records) != 1 || records[0].ID != "3" {
+		t.Fatalf("SetRecords = %+v, want the existing record (ID 3) to be updated", records)
+	}
+}
+
+// TestGetRecordsPagination proves GetRecords follows every page of a zone's
+// records rather than returning just the first, and that TTLs (returned by
+// DNSimple in seconds) are converted to time.Duration correctly.
+func TestGetRecordsPagination(t *testing.T) {
+	pages := [][]zoneRecordJSON{
+		{{ID: 1, Type: "TXT", Name: "a", Content: "one", TTL: 60}},
+		{{ID: 2, Type: "TXT", Name: "b", Content: "two", TTL: 120}},
+		{{ID: 3, Type: "TXT", Name: "c", Content: "three", TTL: 300}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/1/zones/example.com/records", func(w http.ResponseWriter, r *http.Request) {
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			var err error
+			page, err = strconv.Atoi(p)
+			if err != nil {
+				t.Fatalf("parsing page query param: %v", err)
+			}
+		}
+		if page < 1 || page > len(pages) {
+			t.Fatalf("requested out-of-range page %d", page)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": pages[page-1],
+			"pagination": map[string]any{
+				"current_page": page,
+				"total_pages":  len(pages),
+			},
+		})
+	})
+	server := newTestServer(t, mux)
+
+	p := &Provider{APIAccessToken: "test-token", APIURL: server.URL}
+
+	records, err := p.GetRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("GetRecords returned error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("GetRecords returned %d records, want 3 (one per page)", len(records))
+	}
+
+	want := map[string]time.Duration{"1": 60 * time.Second, "2": 120 * time.Second, "3": 300 * time.Second}
+	for _, r := range records {
+		if r.TTL != want[r.ID] {
+			t.Errorf("record %s TTL = %v, want %v", r.ID, r.TTL, want[r.ID])
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package dnsimple
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 4
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// retryTransport is an http.RoundTripper that retries requests which fail due
+// to DNSimple's per-account rate limiting (HTTP 429) or transient upstream
+// errors (502/503/504, or a net.Error reporting Timeout/Temporary), honoring
+// the Retry-After header and an exponential backoff with full jitter otherwise.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp, err = t.next.RoundTrip(req)
+
+		delay, retryable := t.retryDelay(attempt, resp, err)
+		if !retryable || attempt >= t.maxRetries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay decides whether the response or error warrants a retry and, if
+// so, how long to wait before trying again.
+func (t *retryTransport) retryDelay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		var netErr net.Error
+		if ok := isNetError(err, &netErr); ok && (netErr.Timeout() || isTemporary(netErr)) {
+			return t.backoff(attempt), true
+		}
+		return 0, false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return maxDuration(t.retryAfter(resp), t.backoff(attempt)), true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return t.backoff(attempt), true
+	default:
+		return 0, false
+	}
+}
+
+// backoff returns base*2^attempt with full jitter, capped at maxDelay.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	d := t.baseDelay * time.Duration(1<<uint(attempt))
+	if d > t.maxDelay {
+		d = t.maxDelay
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// retryAfter parses the Retry-After header, in either delta-seconds or
+// HTTP-date form, capped at maxDelay. It returns 0 if the header is absent
+// or unparseable.
+func (t *retryTransport) retryAfter(resp *http.Response) time.Duration {
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil {
+		d := time.Duration(secs) * time.Second
+		if d > t.maxDelay {
+			d = t.maxDelay
+		}
+		return d
+	}
+	if when, err := http.ParseTime(h); err == nil {
+		d := time.Until(when)
+		if d < 0 {
+			return 0
+		}
+		if d > t.maxDelay {
+			d = t.maxDelay
+		}
+		return d
+	}
+	return 0
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// isTemporary reports whether err implements the deprecated but still widely
+// used Temporary() bool method.
+func isTemporary(err net.Error) bool {
+	type temporary interface {
+		Temporary() bool
+	}
+	te, ok := err.(temporary)
+	return ok && te.Temporary()
+}
+
+// isNetError reports whether err is (or wraps) a net.Error, storing the match in target.
+func isNetError(err error, target *net.Error) bool {
+	for e := err; e != nil; {
+		if ne, ok := e.(net.Error); ok {
+			*target = ne
+			return true
+		}
+		unwrapper, ok := e.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		e = unwrapper.Unwrap()
+	}
+	return false
+}
+
+// retryTransport builds a retryTransport wrapping next, filling in Provider's
+// MaxRetries/RetryBaseDelay/RetryMaxDelay or their defaults when unset.
+func (p *Provider) retryTransport(next http.RoundTripper) http.RoundTripper {
+	maxRetries := p.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	baseDelay := p.RetryBaseDelay
+	if baseDelay == 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	maxDelay := p.RetryMaxDelay
+	if maxDelay == 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	return &retryTransport{
+		next:       next,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		maxDelay:   maxDelay,
+	}
+}